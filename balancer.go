@@ -0,0 +1,117 @@
+package dbrouter
+
+import (
+	"database/sql"
+	"math/rand"
+	"sync/atomic"
+)
+
+// Replica pairs a candidate RO replica's *sql.DB with its original index
+// into DBImplementation's configured RO replica list. Pick is only ever
+// shown the subset of replicas currently eligible (healthy, not draining,
+// not lagging), so that subset's positions don't match the configured slots
+// it was filtered from; balancers whose decision depends on replica
+// identity (e.g. WeightedBalancer's per-replica weight) must key off Index,
+// not position, to avoid silently misapplying one replica's configuration
+// to another.
+type Replica struct {
+	Index int
+	DB    *sql.DB
+}
+
+// LoadBalancer picks which of the currently healthy RO replicas a read
+// should be routed to. Pick receives the healthy replicas only (unhealthy
+// ones are filtered out before it is called) and returns an index into that
+// slice.
+type LoadBalancer interface {
+	Pick(replicas []Replica) int
+}
+
+// RoundRobinBalancer cycles through replicas in order, wrapping around. It
+// is the default balancer used by Open and WrapDBs.
+type RoundRobinBalancer struct {
+	counter uint64
+}
+
+// NewRoundRobinBalancer returns a RoundRobinBalancer.
+func NewRoundRobinBalancer() *RoundRobinBalancer {
+	return &RoundRobinBalancer{}
+}
+
+// Pick returns the next replica in round-robin order.
+func (b *RoundRobinBalancer) Pick(replicas []Replica) int {
+	return int(atomic.AddUint64(&b.counter, 1) % uint64(len(replicas)))
+}
+
+// RandomBalancer picks a replica uniformly at random.
+type RandomBalancer struct{}
+
+// NewRandomBalancer returns a RandomBalancer.
+func NewRandomBalancer() *RandomBalancer {
+	return &RandomBalancer{}
+}
+
+// Pick returns a uniformly random replica index.
+func (b *RandomBalancer) Pick(replicas []Replica) int {
+	return rand.Intn(len(replicas))
+}
+
+// WeightedBalancer picks a replica at random, weighted by a per-replica
+// weight supplied at construction. Weights[i] is the weight of replica i;
+// replicas beyond len(Weights), or with a non-positive weight, default to a
+// weight of 1.
+type WeightedBalancer struct {
+	Weights []int
+}
+
+// NewWeightedBalancer returns a WeightedBalancer using the given per-replica weights.
+func NewWeightedBalancer(weights []int) *WeightedBalancer {
+	return &WeightedBalancer{Weights: weights}
+}
+
+func (b *WeightedBalancer) weight(i int) int {
+	if i < len(b.Weights) && b.Weights[i] > 0 {
+		return b.Weights[i]
+	}
+	return 1
+}
+
+// Pick returns a replica index chosen at random, proportional to its weight.
+// Weights are looked up by each candidate's original Index, not its position
+// in replicas, so a replica keeps its configured weight even when other
+// replicas have been filtered out ahead of it.
+func (b *WeightedBalancer) Pick(replicas []Replica) int {
+	total := 0
+	for _, r := range replicas {
+		total += b.weight(r.Index)
+	}
+	r := rand.Intn(total)
+	for i, rep := range replicas {
+		r -= b.weight(rep.Index)
+		if r < 0 {
+			return i
+		}
+	}
+	return len(replicas) - 1
+}
+
+// LeastInUseBalancer picks the replica with the fewest connections currently
+// checked out of its pool, per sql.DB.Stats().InUse.
+type LeastInUseBalancer struct{}
+
+// NewLeastInUseBalancer returns a LeastInUseBalancer.
+func NewLeastInUseBalancer() *LeastInUseBalancer {
+	return &LeastInUseBalancer{}
+}
+
+// Pick returns the index of the replica with the lowest in-use connection count.
+func (b *LeastInUseBalancer) Pick(replicas []Replica) int {
+	best := 0
+	bestInUse := replicas[0].DB.Stats().InUse
+	for i := 1; i < len(replicas); i++ {
+		if inUse := replicas[i].DB.Stats().InUse; inUse < bestInUse {
+			best, bestInUse = i, inUse
+		}
+	}
+	return best
+}
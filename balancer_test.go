@@ -0,0 +1,40 @@
+package dbrouter
+
+import (
+	"database/sql"
+	"testing"
+)
+
+// TestWeightedBalancerUsesOriginalIndex guards against a regression where
+// WeightedBalancer looked up weights by a candidate's position in the
+// filtered replicas slice instead of its original configured slot. With
+// replica 0 filtered out (unhealthy, draining, or lagging), replica 1 would
+// shift into position 0 and silently inherit replica 0's weight.
+func TestWeightedBalancerUsesOriginalIndex(t *testing.T) {
+	b := NewWeightedBalancer([]int{100, 1, 1})
+
+	// Replica 0 has been filtered out; only replicas 1 and 2 remain, keyed
+	// by their original Index.
+	db1, db2 := &sql.DB{}, &sql.DB{}
+	candidates := []Replica{
+		{Index: 1, DB: db1},
+		{Index: 2, DB: db2},
+	}
+
+	counts := map[int]int{}
+	const trials = 2000
+	for i := 0; i < trials; i++ {
+		idx := b.Pick(candidates)
+		counts[candidates[idx].Index]++
+	}
+
+	// Replica 0's weight of 100 must never be applied to whatever sits at
+	// position 0 of the filtered slice; both remaining replicas have weight
+	// 1, so the split should be roughly even, not ~99/1.
+	if counts[1] == 0 || counts[2] == 0 {
+		t.Fatalf("expected both replica 1 and 2 to be picked, got counts %v", counts)
+	}
+	if ratio := float64(counts[1]) / float64(counts[2]); ratio < 0.5 || ratio > 2 {
+		t.Errorf("expected roughly even split between equally-weighted replicas 1 and 2, got counts %v", counts)
+	}
+}
@@ -0,0 +1,72 @@
+package dbrouter
+
+import (
+	"context"
+	"database/sql"
+)
+
+// Conn represents a single physical connection checked out from the RW
+// database, mirroring *sql.Conn. All operations issued through Conn run on
+// that one connection, which matters for session-scoped state such as
+// advisory locks, temp tables, or SET statements.
+type Conn interface {
+	BeginTx(ctx context.Context, opts *sql.TxOptions) (Tx, error)
+	Close() error
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	PingContext(ctx context.Context) error
+	PrepareContext(ctx context.Context, query string) (Stmt, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// conn is the internal implementation of the Conn interface. It wraps a
+// *sql.Conn obtained from the RW database.
+type conn struct {
+	db      *DBImplementation
+	sqlConn *sql.Conn
+}
+
+// BeginTx starts a transaction on this connection, pinning the resulting Tx
+// (and any Stmt prepared within it) to the same physical connection.
+func (c *conn) BeginTx(ctx context.Context, opts *sql.TxOptions) (Tx, error) {
+	sqlTx, err := c.sqlConn.BeginTx(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	return &tx{db: c.db, sqlTx: sqlTx}, nil
+}
+
+// Close returns the connection to the RW database's connection pool.
+func (c *conn) Close() error {
+	return c.sqlConn.Close()
+}
+
+// ExecContext executes a query on this connection without returning rows.
+func (c *conn) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	return c.sqlConn.ExecContext(ctx, query, args...)
+}
+
+// PingContext verifies that this connection is still alive.
+func (c *conn) PingContext(ctx context.Context) error {
+	return c.sqlConn.PingContext(ctx)
+}
+
+// PrepareContext prepares query on this connection. The returned Stmt is
+// pinned to it and never fans out to RO databases.
+func (c *conn) PrepareContext(ctx context.Context, query string) (Stmt, error) {
+	sqlStmt, err := c.sqlConn.PrepareContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	return &stmt{db: c.db, pinned: sqlStmt}, nil
+}
+
+// QueryContext executes a query on this connection and returns rows.
+func (c *conn) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	return c.sqlConn.QueryContext(ctx, query, args...)
+}
+
+// QueryRowContext executes a query on this connection and returns at most one row.
+func (c *conn) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	return c.sqlConn.QueryRowContext(ctx, query, args...)
+}
@@ -0,0 +1,40 @@
+package dbrouter
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+)
+
+// TestConnBeginTxPinsToConnection verifies that a Tx started from a Conn
+// stays pinned to that Conn's own connection, the same as a Tx started
+// directly from the DBImplementation.
+func TestConnBeginTxPinsToConnection(t *testing.T) {
+	dbImplementation := &DBImplementation{readWriteDB: newFakeDB(), readOnlyDBs: []*sql.DB{newFakeDB()}}
+
+	c, err := dbImplementation.Conn(context.Background())
+	if err != nil {
+		t.Fatalf("Conn: %v", err)
+	}
+	defer c.Close()
+
+	txn, err := c.BeginTx(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("BeginTx: %v", err)
+	}
+	defer txn.Rollback()
+
+	s, err := txn.Prepare("SELECT 1")
+	if err != nil {
+		t.Fatalf("Prepare: %v", err)
+	}
+	defer s.Close()
+
+	internal, ok := s.(*stmt)
+	if !ok {
+		t.Fatalf("expected *stmt, got %T", s)
+	}
+	if internal.pinned == nil {
+		t.Fatal("expected Conn-started Tx.Prepare to return a pinned stmt")
+	}
+}
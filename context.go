@@ -0,0 +1,53 @@
+package dbrouter
+
+import (
+	"context"
+	"time"
+)
+
+// ctxKey is an unexported type for the context keys defined below, so they
+// cannot collide with keys set by other packages.
+type ctxKey int
+
+const (
+	ctxKeyMaxLag ctxKey = iota
+	ctxKeyForcePrimary
+)
+
+// WithMaxLag returns a context that, when passed to QueryContext,
+// QueryRowContext, or Stmt.QueryContext, restricts routing to RO replicas
+// whose last-measured replication lag is at most d. Replicas exceeding d, or
+// with no lag measurement yet, are skipped; if none qualify, the read falls
+// back to the RW database. It has no effect unless a LagProbe has been
+// configured via WithLagProbe or SetLagProbe.
+func WithMaxLag(ctx context.Context, d time.Duration) context.Context {
+	return context.WithValue(ctx, ctxKeyMaxLag, d)
+}
+
+// maxLagFromContext returns the duration set by WithMaxLag, if any.
+func maxLagFromContext(ctx context.Context) (time.Duration, bool) {
+	d, ok := ctx.Value(ctxKeyMaxLag).(time.Duration)
+	return d, ok
+}
+
+// WithForcePrimary returns a context that, when passed to QueryContext,
+// QueryRowContext, or Stmt.QueryContext, routes the read to the RW database
+// and bypasses RO replicas entirely, regardless of their health or lag.
+func WithForcePrimary(ctx context.Context) context.Context {
+	return context.WithValue(ctx, ctxKeyForcePrimary, true)
+}
+
+// WithReadFromPrimary is WithForcePrimary under a name suited to its most
+// common use: a caller that just wrote through the RW database and needs
+// its next read to observe that write (read-your-writes), rather than risk
+// an RO replica that has not caught up yet.
+func WithReadFromPrimary(ctx context.Context) context.Context {
+	return WithForcePrimary(ctx)
+}
+
+// forcePrimaryFromContext reports whether WithForcePrimary (or
+// WithReadFromPrimary) was set on ctx.
+func forcePrimaryFromContext(ctx context.Context) bool {
+	v, _ := ctx.Value(ctxKeyForcePrimary).(bool)
+	return v
+}
@@ -5,14 +5,19 @@ import (
 	"database/sql"
 	"database/sql/driver"
 	"strings"
-	"sync/atomic"
+	"sync"
 	"time"
 )
 
 type DB interface {
-	Begin() (*sql.Tx, error)
-	BeginTx(ctx context.Context, opts *sql.TxOptions) (*sql.Tx, error)
+	Begin() (Tx, error)
+	BeginTx(ctx context.Context, opts *sql.TxOptions) (Tx, error)
+	// BeginSQLTx starts a transaction on the RW database and returns the raw
+	// *sql.Tx, for callers that depended on Begin/BeginTx returning *sql.Tx
+	// before Tx was introduced.
+	BeginSQLTx(ctx context.Context, opts *sql.TxOptions) (*sql.Tx, error)
 	Close() error
+	Conn(ctx context.Context) (Conn, error)
 	Driver() driver.Driver
 	Exec(query string, args ...interface{}) (sql.Result, error)
 	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
@@ -35,37 +40,161 @@ type DBImplementation struct {
 	readWriteDB      *sql.DB   // The primary (master) database for read-write operations.
 	readOnlyDBs      []*sql.DB // List of replication databases for read-only operations.
 	totalConnections int       // Total number of database connections (1 RW + n RO databases).
-	readOnlyCount    uint64    // Counter for load balancing read-only queries (used in round-robin).
+
+	loadBalancer        LoadBalancer   // Strategy used to pick a healthy RO replica for reads.
+	healthCheckInterval time.Duration  // How often RO replicas are pinged by health.
+	health              *healthChecker // Tracks which RO replicas are currently reachable.
+	concurrencyLimit    int            // Bounds fan-out across physical databases; <= 0 means unbounded.
+
+	lagProbe         LagProbe      // Driver-specific replication-lag probe; nil disables lag tracking.
+	lagCheckInterval time.Duration // How often RO replicas are probed for lag.
+	lag              *lagChecker   // Tracks the last measured replication lag per RO replica.
+
+	roMu      sync.RWMutex       // Guards readOnlyDBs[i] swaps performed by ReplaceReplica.
+	replicas  []*replicaState    // Per-replica maintenance flag and in-flight query counter.
+	stmtsMu   sync.Mutex         // Guards liveStmts.
+	liveStmts map[*stmt]struct{} // Open statements prepared against all databases, for re-preparation by ReplaceReplica.
 }
 
 // Open initializes the connection to all physical databases (both RW and RO) concurrently.
 // `dataSourceNames` is a semicolon-separated list where the first entry is the RW database,
-// and subsequent entries are RO databases.
-func Open(driverName, dataSourceName string) (*DBImplementation, error) {
+// and subsequent entries are RO databases. Opts can override the default load balancer
+// (round-robin) and health-check interval.
+func Open(driverName, dataSourceName string, opts ...Option) (*DBImplementation, error) {
 	conns := strings.Split(dataSourceName, ";")
 	dbImplementation := &DBImplementation{
 		readOnlyDBs:      make([]*sql.DB, len(conns)-1), // Allocate space for all RO databases.
 		totalConnections: len(conns),                    // Total connections = 1 RW + (n - 1) RO databases.
 	}
 
+	// Apply opts once up front so WithMaxConcurrency is already in effect for
+	// openConnections below; applyOptions re-applies them once the RO
+	// replicas exist, which is harmless since every Option only assigns a
+	// field.
+	for _, opt := range opts {
+		opt(dbImplementation)
+	}
+
 	// Open all databases (RW and RO) concurrently.
 	err := dbImplementation.openConnections(driverName, conns)
 	if err != nil {
 		return nil, err
 	}
 
+	dbImplementation.applyOptions(opts)
 	return dbImplementation, nil
 }
 
+// applyOptions sets defaults for the load balancer and health-check interval,
+// applies opts on top, and starts the background health checker. It is
+// called once the RO replicas are known, by Open and WrapDBsWithOptions.
+func (dbImplementation *DBImplementation) applyOptions(opts []Option) {
+	dbImplementation.loadBalancer = NewRoundRobinBalancer()
+	dbImplementation.healthCheckInterval = defaultHealthCheckInterval
+	for _, opt := range opts {
+		opt(dbImplementation)
+	}
+	dbImplementation.health = newHealthChecker(len(dbImplementation.readOnlyDBs))
+	if len(dbImplementation.readOnlyDBs) > 0 {
+		dbImplementation.health.start(dbImplementation.snapshotReplicas, dbImplementation.healthCheckInterval)
+	}
+	dbImplementation.replicas = make([]*replicaState, len(dbImplementation.readOnlyDBs))
+	for i := range dbImplementation.replicas {
+		dbImplementation.replicas[i] = &replicaState{}
+	}
+	dbImplementation.liveStmts = make(map[*stmt]struct{})
+
+	if dbImplementation.lagProbe != nil {
+		if dbImplementation.lagCheckInterval == 0 {
+			dbImplementation.lagCheckInterval = defaultLagCheckInterval
+		}
+		dbImplementation.lag = newLagChecker(dbImplementation.lagProbe, len(dbImplementation.readOnlyDBs))
+		if len(dbImplementation.readOnlyDBs) > 0 {
+			dbImplementation.lag.start(dbImplementation.snapshotReplicas, dbImplementation.lagCheckInterval)
+		}
+	}
+}
+
+// SetLoadBalancer replaces the strategy used to pick a healthy RO replica for reads.
+func (dbImplementation *DBImplementation) SetLoadBalancer(lb LoadBalancer) {
+	dbImplementation.loadBalancer = lb
+}
+
+// SetMaxConcurrency changes the bound on how many physical databases Open,
+// OpenMulti, Close, Ping(Context), Prepare(Context), and Stmt.Close operate
+// on at once. n <= 0 means unbounded.
+func (dbImplementation *DBImplementation) SetMaxConcurrency(n int) {
+	dbImplementation.concurrencyLimit = n
+}
+
+// SetHealthCheckInterval changes how often RO replicas are pinged, restarting
+// the background health-check loop with the new interval.
+func (dbImplementation *DBImplementation) SetHealthCheckInterval(interval time.Duration) {
+	dbImplementation.health.stop()
+	dbImplementation.healthCheckInterval = interval
+	dbImplementation.health = newHealthChecker(len(dbImplementation.readOnlyDBs))
+	if len(dbImplementation.readOnlyDBs) > 0 {
+		dbImplementation.health.start(dbImplementation.snapshotReplicas, interval)
+	}
+}
+
+// SetLagProbe enables or replaces replication-lag tracking, restarting the
+// background probe loop with the new probe and interval. Passing a nil probe
+// disables lag tracking, after which WithMaxLag has no effect.
+func (dbImplementation *DBImplementation) SetLagProbe(probe LagProbe, interval time.Duration) {
+	if dbImplementation.lag != nil {
+		dbImplementation.lag.stop()
+		dbImplementation.lag = nil
+	}
+	dbImplementation.lagProbe = probe
+	dbImplementation.lagCheckInterval = interval
+	if probe == nil {
+		return
+	}
+	if interval == 0 {
+		interval = defaultLagCheckInterval
+		dbImplementation.lagCheckInterval = interval
+	}
+	dbImplementation.lag = newLagChecker(probe, len(dbImplementation.readOnlyDBs))
+	if len(dbImplementation.readOnlyDBs) > 0 {
+		dbImplementation.lag.start(dbImplementation.snapshotReplicas, interval)
+	}
+}
+
+// Stats reports connection pool statistics for the RW database, plus pool
+// statistics and health state for every RO replica, so operators can
+// observe replica behavior.
+func (dbImplementation *DBImplementation) Stats() (rw sql.DBStats, replicas []ReplicaStats) {
+	rw = dbImplementation.readWriteDB.Stats()
+	roDBs := dbImplementation.snapshotReplicas()
+	replicas = make([]ReplicaStats, len(roDBs))
+	for i, roDB := range roDBs {
+		replicas[i] = ReplicaStats{Stats: roDB.Stats(), Healthy: dbImplementation.health.isHealthy(i)}
+	}
+	return rw, replicas
+}
+
+// ReplicaStats reports a single RO replica's connection pool statistics and
+// whether the health checker currently considers it reachable.
+type ReplicaStats struct {
+	Stats   sql.DBStats
+	Healthy bool
+}
+
 // Close gracefully closes all database connections (RW and RO) concurrently.
 func (dbImplementation *DBImplementation) Close() error {
-	return doConcurrent(dbImplementation.totalConnections, func(i int) error {
+	dbImplementation.health.stop()
+	if dbImplementation.lag != nil {
+		dbImplementation.lag.stop()
+	}
+	roDBs := dbImplementation.snapshotReplicas()
+	return doConcurrentLimit(context.Background(), dbImplementation.totalConnections, dbImplementation.concurrencyLimit, func(_ context.Context, i int) error {
 		if i == 0 {
 			// Close the RW (master) database connection.
 			return dbImplementation.readWriteDB.Close()
 		}
 		// Close the RO (replica) database connection.
-		return dbImplementation.readOnlyDBs[i-1].Close()
+		return roDBs[i-1].Close()
 	})
 }
 
@@ -74,17 +203,42 @@ func (dbImplementation *DBImplementation) Driver() driver.Driver {
 	return dbImplementation.readWriteDB.Driver()
 }
 
-// Begin initiates a transaction on the RW (master) database.
-func (dbImplementation *DBImplementation) Begin() (*sql.Tx, error) {
-	return dbImplementation.ReadWrite().Begin()
+// Begin initiates a transaction on the RW (master) database. The returned Tx
+// pins every statement prepared within it to the transaction's own
+// connection, so reads inside the transaction observe the writes made
+// earlier in that same transaction.
+func (dbImplementation *DBImplementation) Begin() (Tx, error) {
+	return dbImplementation.BeginTx(context.Background(), nil)
 }
 
 // BeginTx starts a transaction on the RW database with the given context and transaction options.
 // The transaction options (TxOptions) can be nil, and if the isolation level is unsupported by the driver, it returns an error.
-func (dbImplementation *DBImplementation) BeginTx(ctx context.Context, opts *sql.TxOptions) (*sql.Tx, error) {
+func (dbImplementation *DBImplementation) BeginTx(ctx context.Context, opts *sql.TxOptions) (Tx, error) {
+	sqlTx, err := dbImplementation.BeginSQLTx(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	return &tx{db: dbImplementation, sqlTx: sqlTx}, nil
+}
+
+// BeginSQLTx starts a transaction on the RW database and returns the raw
+// *sql.Tx, preserved for callers that relied on Begin/BeginTx returning
+// *sql.Tx before Tx was introduced.
+func (dbImplementation *DBImplementation) BeginSQLTx(ctx context.Context, opts *sql.TxOptions) (*sql.Tx, error) {
 	return dbImplementation.ReadWrite().BeginTx(ctx, opts)
 }
 
+// Conn returns a wrapper around a single physical connection checked out
+// from the RW database, mirroring (*sql.DB).Conn. Every operation issued
+// through the returned Conn runs on that one connection.
+func (dbImplementation *DBImplementation) Conn(ctx context.Context) (Conn, error) {
+	sqlConn, err := dbImplementation.ReadWrite().Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &conn{db: dbImplementation, sqlConn: sqlConn}, nil
+}
+
 // Exec executes a query on the RW database without returning rows (e.g., INSERT, UPDATE).
 // Arguments are provided for query placeholders.
 func (dbImplementation *DBImplementation) Exec(query string, args ...interface{}) (sql.Result, error) {
@@ -98,93 +252,106 @@ func (dbImplementation *DBImplementation) ExecContext(ctx context.Context, query
 
 // Ping verifies that all database connections (RW and RO) are still alive, reconnecting if necessary.
 func (dbImplementation *DBImplementation) Ping() error {
-	return doConcurrent(dbImplementation.totalConnections, func(i int) error {
-		if i == 0 {
-			return dbImplementation.readWriteDB.Ping()
-		}
-		return dbImplementation.readOnlyDBs[i-1].Ping()
-	})
+	return dbImplementation.PingContext(context.Background())
 }
 
 // PingContext performs the same operation as Ping but allows passing a context for cancellation or timeouts.
+// If one database fails to respond, its sibling PingContext calls are canceled rather than left to run out their timeout.
 func (dbImplementation *DBImplementation) PingContext(ctx context.Context) error {
-	return doConcurrent(dbImplementation.totalConnections, func(i int) error {
+	roDBs := dbImplementation.snapshotReplicas()
+	return doConcurrentLimit(ctx, dbImplementation.totalConnections, dbImplementation.concurrencyLimit, func(ctx context.Context, i int) error {
 		if i == 0 {
 			return dbImplementation.readWriteDB.PingContext(ctx)
 		}
-		return dbImplementation.readOnlyDBs[i-1].PingContext(ctx)
+		return roDBs[i-1].PingContext(ctx)
 	})
 }
 
 // Prepare creates a prepared statement for execution on the RW and RO databases.
 // It generates a statement for each database concurrently.
 func (dbImplementation *DBImplementation) Prepare(query string) (Stmt, error) {
-	stmt := &stmt{db: dbImplementation}
-	roStmts := make([]*sql.Stmt, len(dbImplementation.readOnlyDBs))
-	err := doConcurrent(dbImplementation.totalConnections, func(i int) (err error) {
+	stmt := &stmt{db: dbImplementation, query: query, returning: hasReturning(query)}
+	roDBs := dbImplementation.snapshotReplicas()
+	roStmts := make([]*sql.Stmt, len(roDBs))
+	err := doConcurrentLimit(context.Background(), dbImplementation.totalConnections, dbImplementation.concurrencyLimit, func(_ context.Context, i int) (err error) {
 		if i == 0 {
 			stmt.rwstmt, err = dbImplementation.readWriteDB.Prepare(query)
 			return err
 		}
 		// Prepare statement for each RO database.
-		roStmts[i-1], err = dbImplementation.readOnlyDBs[i-1].Prepare(query)
+		roStmts[i-1], err = roDBs[i-1].Prepare(query)
 		return err
 	})
 	if err != nil {
 		return nil, err
 	}
 	stmt.rostmts = roStmts
+	dbImplementation.registerStmt(stmt)
 	return stmt, nil
 }
 
 // PrepareContext creates a prepared statement using context for each underlying database (RW and RO).
 // The context is used during the preparation phase, not during execution.
 func (dbImplementation *DBImplementation) PrepareContext(ctx context.Context, query string) (Stmt, error) {
-	stmt := &stmt{db: dbImplementation}
-	roStmts := make([]*sql.Stmt, len(dbImplementation.readOnlyDBs))
-	err := doConcurrent(dbImplementation.totalConnections, func(i int) (err error) {
+	stmt := &stmt{db: dbImplementation, query: query, returning: hasReturning(query)}
+	roDBs := dbImplementation.snapshotReplicas()
+	roStmts := make([]*sql.Stmt, len(roDBs))
+	err := doConcurrentLimit(ctx, dbImplementation.totalConnections, dbImplementation.concurrencyLimit, func(ctx context.Context, i int) (err error) {
 		if i == 0 {
 			stmt.rwstmt, err = dbImplementation.readWriteDB.PrepareContext(ctx, query)
 			return err
 		}
 		// Prepare context-based statement for RO databases.
-		roStmts[i-1], err = dbImplementation.readOnlyDBs[i-1].PrepareContext(ctx, query)
+		roStmts[i-1], err = roDBs[i-1].PrepareContext(ctx, query)
 		return err
 	})
 	if err != nil {
 		return nil, err
 	}
 	stmt.rostmts = roStmts
+	dbImplementation.registerStmt(stmt)
 	return stmt, nil
 }
 
-// Query executes a query on a RO database (selected using round-robin) and returns rows.
+// Query executes a query on a RO database (selected by the configured LoadBalancer) and returns rows.
 // This is typically used for SELECT statements.
 func (dbImplementation *DBImplementation) Query(query string, args ...interface{}) (*sql.Rows, error) {
-	return dbImplementation.ReadOnly().Query(query, args...)
+	db, idx := dbImplementation.pickAndTrackReplicaDB(context.Background())
+	defer dbImplementation.untrackReplica(idx)
+	return db.Query(query, args...)
 }
 
 // QueryContext executes a query with a context on a RO database and returns rows.
+// If ctx carries WithMaxLag or WithForcePrimary/WithReadFromPrimary, routing
+// honors them, falling back to the RW database when no replica qualifies.
 func (dbImplementation *DBImplementation) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
-	return dbImplementation.ReadOnly().QueryContext(ctx, query, args...)
+	db, idx := dbImplementation.pickAndTrackReplicaDB(ctx)
+	defer dbImplementation.untrackReplica(idx)
+	return db.QueryContext(ctx, query, args...)
 }
 
 // QueryRow executes a query on a RO database and returns at most one row.
 // This method always returns a non-nil result, with errors deferred until Scan is called.
 func (dbImplementation *DBImplementation) QueryRow(query string, args ...interface{}) *sql.Row {
-	return dbImplementation.ReadOnly().QueryRow(query, args...)
+	db, idx := dbImplementation.pickAndTrackReplicaDB(context.Background())
+	defer dbImplementation.untrackReplica(idx)
+	return db.QueryRow(query, args...)
 }
 
 // QueryRowContext executes a query on a RO database and returns a single row with the provided context.
+// If ctx carries WithMaxLag or WithForcePrimary/WithReadFromPrimary, routing
+// honors them, falling back to the RW database when no replica qualifies.
 func (dbImplementation *DBImplementation) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
-	return dbImplementation.ReadOnly().QueryRowContext(ctx, query, args...)
+	db, idx := dbImplementation.pickAndTrackReplicaDB(ctx)
+	defer dbImplementation.untrackReplica(idx)
+	return db.QueryRowContext(ctx, query, args...)
 }
 
 // SetMaxIdleConns sets the maximum number of idle connections allowed for each database (RW and RO).
 // If n <= 0, no idle connections will be retained.
 func (dbImplementation *DBImplementation) SetMaxIdleConns(n int) {
 	dbImplementation.readWriteDB.SetMaxIdleConns(n)
-	for _, roDB := range dbImplementation.readOnlyDBs {
+	for _, roDB := range dbImplementation.snapshotReplicas() {
 		roDB.SetMaxIdleConns(n)
 	}
 }
@@ -193,7 +360,7 @@ func (dbImplementation *DBImplementation) SetMaxIdleConns(n int) {
 // If n <= 0, there is no limit on the number of open connections.
 func (dbImplementation *DBImplementation) SetMaxOpenConns(n int) {
 	dbImplementation.readWriteDB.SetMaxOpenConns(n)
-	for _, roDB := range dbImplementation.readOnlyDBs {
+	for _, roDB := range dbImplementation.snapshotReplicas() {
 		roDB.SetMaxOpenConns(n)
 	}
 }
@@ -201,7 +368,7 @@ func (dbImplementation *DBImplementation) SetMaxOpenConns(n int) {
 // SetConnMaxLifetime sets the maximum amount of time a connection can remain open before being closed.
 func (dbImplementation *DBImplementation) SetConnMaxLifetime(d time.Duration) {
 	dbImplementation.readWriteDB.SetConnMaxLifetime(d)
-	for _, roDB := range dbImplementation.readOnlyDBs {
+	for _, roDB := range dbImplementation.snapshotReplicas() {
 		roDB.SetConnMaxLifetime(d)
 	}
 }
@@ -209,17 +376,17 @@ func (dbImplementation *DBImplementation) SetConnMaxLifetime(d time.Duration) {
 // SetConnMaxIdleTime sets the maximum amount of time a connection can remain idle before being closed.
 func (dbImplementation *DBImplementation) SetConnMaxIdleTime(d time.Duration) {
 	dbImplementation.readWriteDB.SetConnMaxIdleTime(d)
-	for i := range dbImplementation.readOnlyDBs {
-		dbImplementation.readOnlyDBs[i].SetConnMaxIdleTime(d)
+	for _, roDB := range dbImplementation.snapshotReplicas() {
+		roDB.SetConnMaxIdleTime(d)
 	}
 }
 
-// ReadOnly returns a read-only database (selected via round-robin) to distribute read queries evenly.
+// ReadOnly returns a read-only database, selected by the configured
+// LoadBalancer among the currently healthy, non-draining replicas. If none
+// qualify, it falls back to the RW database.
 func (dbImplementation *DBImplementation) ReadOnly() *sql.DB {
-	if dbImplementation.totalConnections == 1 {
-		return dbImplementation.readWriteDB // No read-only databases available.
-	}
-	return dbImplementation.readOnlyDBs[dbImplementation.roundRobin(len(dbImplementation.readOnlyDBs))]
+	db, _ := dbImplementation.pickReplicaDB(context.Background())
+	return db
 }
 
 // ReadWrite returns the read-write (master) database for write operations.
@@ -227,14 +394,115 @@ func (dbImplementation *DBImplementation) ReadWrite() *sql.DB {
 	return dbImplementation.readWriteDB
 }
 
-// roundRobin selects a read-only database based on a round-robin load balancing algorithm.
-func (dbImplementation *DBImplementation) roundRobin(n int) int {
-	return int(atomic.AddUint64(&dbImplementation.readOnlyCount, 1) % uint64(n))
+// snapshotReplicas returns a copy of readOnlyDBs taken under roMu.RLock, for
+// callers — such as the health and lag checker background goroutines, Close,
+// PingContext, Prepare(Context) and Stats — that read the whole slice and
+// must not race with ReplaceReplica swapping an element in place.
+func (dbImplementation *DBImplementation) snapshotReplicas() []*sql.DB {
+	dbImplementation.roMu.RLock()
+	defer dbImplementation.roMu.RUnlock()
+	out := make([]*sql.DB, len(dbImplementation.readOnlyDBs))
+	copy(out, dbImplementation.readOnlyDBs)
+	return out
+}
+
+// pickReplicaDB is like pickReplicaCtx but also returns the chosen *sql.DB
+// directly, falling back to the RW database when no replica qualifies.
+func (dbImplementation *DBImplementation) pickReplicaDB(ctx context.Context) (db *sql.DB, idx int) {
+	idx = dbImplementation.pickReplicaCtx(ctx)
+	if idx == -1 {
+		return dbImplementation.readWriteDB, -1
+	}
+	dbImplementation.roMu.RLock()
+	defer dbImplementation.roMu.RUnlock()
+	return dbImplementation.readOnlyDBs[idx], idx
+}
+
+// pickReplica is pickReplicaCtx with a background context, for call sites
+// with no context of their own. Query, QueryRow, Stmt.Query and
+// Stmt.QueryRow do not use it; they pick through pickAndTrackReplica.
+func (dbImplementation *DBImplementation) pickReplica() int {
+	return dbImplementation.pickReplicaCtx(context.Background())
+}
+
+// pickAndTrackReplica is pickReplicaCtx, but additionally increments the
+// chosen replica's in-flight counter before returning, as part of the same
+// step. Picking and tracking separately left a window where WaitForDrain
+// could observe inFlight == 0 and let its caller tear down the replica
+// after a concurrent query had already picked it but before it had tracked
+// itself. If the replica turns out to have entered maintenance in that same
+// window, the pick is backed out and retried against the remaining
+// candidates. The caller must call untrackReplica with the returned index
+// exactly once when the query or statement execution it picked for has
+// finished; idx may be -1 (the RW database), for which untrackReplica is a
+// no-op.
+func (dbImplementation *DBImplementation) pickAndTrackReplica(ctx context.Context) int {
+	for {
+		idx := dbImplementation.pickReplicaCtx(ctx)
+		if idx == -1 {
+			return -1
+		}
+		dbImplementation.trackReplica(idx)
+		if dbImplementation.replicas[idx].inMaintenance() {
+			dbImplementation.untrackReplica(idx)
+			continue
+		}
+		return idx
+	}
+}
+
+// pickAndTrackReplicaDB is pickAndTrackReplica, but also returns the chosen
+// *sql.DB directly, falling back to the RW database when no replica
+// qualifies.
+func (dbImplementation *DBImplementation) pickAndTrackReplicaDB(ctx context.Context) (db *sql.DB, idx int) {
+	idx = dbImplementation.pickAndTrackReplica(ctx)
+	if idx == -1 {
+		return dbImplementation.readWriteDB, -1
+	}
+	dbImplementation.roMu.RLock()
+	defer dbImplementation.roMu.RUnlock()
+	return dbImplementation.readOnlyDBs[idx], idx
+}
+
+// pickReplicaCtx returns the index into readOnlyDBs chosen by the configured
+// LoadBalancer among the replicas that are currently healthy, not under
+// maintenance, and (if ctx carries WithMaxLag and a LagProbe is configured)
+// not lagging beyond the given threshold. It returns -1, meaning callers
+// should use the RW database instead, if ctx carries WithForcePrimary /
+// WithReadFromPrimary or if no replica qualifies.
+func (dbImplementation *DBImplementation) pickReplicaCtx(ctx context.Context) int {
+	if forcePrimaryFromContext(ctx) {
+		return -1
+	}
+	if len(dbImplementation.readOnlyDBs) == 0 {
+		return -1
+	}
+	maxLag, hasMaxLag := maxLagFromContext(ctx)
+	candidates := make([]int, 0, len(dbImplementation.readOnlyDBs))
+	for _, idx := range dbImplementation.health.healthyIndexes() {
+		if dbImplementation.replicas[idx].inMaintenance() {
+			continue
+		}
+		if hasMaxLag && dbImplementation.lag != nil && (!dbImplementation.lag.probed(idx) || dbImplementation.lag.lag(idx) > maxLag) {
+			continue
+		}
+		candidates = append(candidates, idx)
+	}
+	if len(candidates) == 0 {
+		return -1
+	}
+	dbImplementation.roMu.RLock()
+	candidateReplicas := make([]Replica, len(candidates))
+	for i, idx := range candidates {
+		candidateReplicas[i] = Replica{Index: idx, DB: dbImplementation.readOnlyDBs[idx]}
+	}
+	dbImplementation.roMu.RUnlock()
+	return candidates[dbImplementation.loadBalancer.Pick(candidateReplicas)]
 }
 
 // openConnections concurrently opens all database connections (RW and RO).
 func (dbImplementation *DBImplementation) openConnections(driverName string, conns []string) error {
-	return doConcurrent(dbImplementation.totalConnections, func(i int) error {
+	return doConcurrentLimit(context.Background(), dbImplementation.totalConnections, dbImplementation.concurrencyLimit, func(_ context.Context, i int) error {
 		var err error
 		if i == 0 {
 			// Open the RW (master) database.
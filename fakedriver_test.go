@@ -0,0 +1,72 @@
+package dbrouter
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"io"
+)
+
+func init() {
+	sql.Register("fakedriver", fakeDriver{})
+}
+
+// newFakeDB opens a *sql.DB backed by fakeDriver, suitable for tests that
+// need a real connection (e.g. to exercise Tx/Conn pinning) without a real
+// database.
+func newFakeDB() *sql.DB {
+	db, err := sql.Open("fakedriver", "")
+	if err != nil {
+		panic(err) // fakeDriver.Open never errors.
+	}
+	return db
+}
+
+// fakeDriver is a minimal database/sql/driver implementation with no actual
+// storage, used to exercise Tx/Conn pinning (tx_test.go, conn_test.go)
+// without a real database.
+type fakeDriver struct{}
+
+func (fakeDriver) Open(name string) (driver.Conn, error) {
+	return &fakeConn{}, nil
+}
+
+type fakeConn struct{}
+
+func (*fakeConn) Prepare(query string) (driver.Stmt, error) {
+	return &fakeStmt{}, nil
+}
+
+func (*fakeConn) Close() error {
+	return nil
+}
+
+func (*fakeConn) Begin() (driver.Tx, error) {
+	return &fakeTx{}, nil
+}
+
+type fakeTx struct{}
+
+func (*fakeTx) Commit() error   { return nil }
+func (*fakeTx) Rollback() error { return nil }
+
+type fakeStmt struct{}
+
+func (*fakeStmt) Close() error  { return nil }
+func (*fakeStmt) NumInput() int { return -1 }
+
+func (*fakeStmt) Exec(args []driver.Value) (driver.Result, error) {
+	return driver.ResultNoRows, nil
+}
+
+func (*fakeStmt) Query(args []driver.Value) (driver.Rows, error) {
+	return &fakeRows{}, nil
+}
+
+// fakeRows reports a single column and no rows.
+type fakeRows struct{}
+
+func (*fakeRows) Columns() []string { return []string{"n"} }
+func (*fakeRows) Close() error      { return nil }
+func (*fakeRows) Next(dest []driver.Value) error {
+	return io.EOF
+}
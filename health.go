@@ -0,0 +1,81 @@
+package dbrouter
+
+import (
+	"database/sql"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// defaultHealthCheckInterval is how often RO replicas are pinged when no
+// interval has been configured via WithHealthCheckInterval or
+// SetHealthCheckInterval.
+const defaultHealthCheckInterval = 5 * time.Second
+
+// healthChecker periodically pings a fixed set of replicas and tracks which
+// ones are currently reachable, so ReadOnly and Stmt.ROStmt can skip failing
+// replicas until they recover.
+type healthChecker struct {
+	healthy []int32 // atomic booleans (0/1), one per replica; starts healthy
+	done    chan struct{}
+	stopped sync.Once
+}
+
+// newHealthChecker returns a healthChecker for n replicas, all initially
+// considered healthy.
+func newHealthChecker(n int) *healthChecker {
+	hc := &healthChecker{
+		healthy: make([]int32, n),
+		done:    make(chan struct{}),
+	}
+	for i := range hc.healthy {
+		hc.healthy[i] = 1
+	}
+	return hc
+}
+
+// start launches the background ping loop every interval, until stop is
+// called. getReplicas is called fresh on every tick rather than captured
+// once, so a concurrent ReplaceReplica swapping in a new *sql.DB is observed
+// safely instead of racing a stale slice.
+func (hc *healthChecker) start(getReplicas func() []*sql.DB, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	go func() {
+		defer ticker.Stop()
+		for {
+			select {
+			case <-hc.done:
+				return
+			case <-ticker.C:
+				for i, db := range getReplicas() {
+					healthy := int32(0)
+					if db.Ping() == nil {
+						healthy = 1
+					}
+					atomic.StoreInt32(&hc.healthy[i], healthy)
+				}
+			}
+		}
+	}()
+}
+
+// stop halts the background ping loop. It is safe to call more than once.
+func (hc *healthChecker) stop() {
+	hc.stopped.Do(func() { close(hc.done) })
+}
+
+// isHealthy reports whether replica i last responded successfully to a ping.
+func (hc *healthChecker) isHealthy(i int) bool {
+	return atomic.LoadInt32(&hc.healthy[i]) == 1
+}
+
+// healthyIndexes returns the indexes of every currently healthy replica.
+func (hc *healthChecker) healthyIndexes() []int {
+	indexes := make([]int, 0, len(hc.healthy))
+	for i := range hc.healthy {
+		if hc.isHealthy(i) {
+			indexes = append(indexes, i)
+		}
+	}
+	return indexes
+}
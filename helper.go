@@ -1,20 +1,63 @@
 package dbrouter
 
-// doConcurrent runs a function concurrently n times and collects any errors.
-// It returns the last non-nil error, if any.
-func doConcurrent(n int, fn func(i int) error) error {
-	errors := make(chan error, n) // Channel to collect errors.
+import (
+	"context"
+	"errors"
+	"fmt"
+)
 
-	for i := 0; i < n; i++ {
-		go func(i int) { errors <- fn(i) }(i) // Launch goroutines to execute fn.
+// doConcurrentLimit runs fn(ctx, i) for every i in [0,n) concurrently and
+// joins every failure into a single error via errors.Join, each one
+// annotated with which physical database produced it (e.g. "replica[2]:
+// connection refused"; i == 0 is annotated "rw"). ctx is passed through to
+// fn, and is canceled as soon as any call fails, so ctx-aware callers
+// (PingContext, PrepareContext, …) can stop their sibling goroutines early
+// instead of waiting out the full timeout. limit optionally bounds how many
+// of the n calls to fn run at once; limit <= 0 means unbounded. A bound
+// matters for topologies with many replicas, to avoid opening hundreds of
+// driver connections at once; one goroutine per index is still started up
+// front, and those beyond the limit wait for a free slot. See WithMaxConcurrency/
+// SetMaxConcurrency for the caller-facing knob that sets limit.
+func doConcurrentLimit(ctx context.Context, n int, limit int, fn func(ctx context.Context, i int) error) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var sem chan struct{}
+	if limit > 0 {
+		sem = make(chan struct{}, limit)
 	}
 
-	var err error
+	errs := make([]error, n)
+	done := make(chan struct{})
+	for i := 0; i < n; i++ {
+		go func(i int) {
+			defer func() { done <- struct{}{} }()
+			if sem != nil {
+				select {
+				case sem <- struct{}{}:
+					defer func() { <-sem }()
+				case <-ctx.Done():
+					errs[i] = annotate(i, ctx.Err())
+					return
+				}
+			}
+			if err := fn(ctx, i); err != nil {
+				errs[i] = annotate(i, err)
+				cancel() // Let siblings bail out early once one call has failed.
+			}
+		}(i)
+	}
 	for i := 0; i < n; i++ {
-		if e := <-errors; e != nil { // Collect errors from all goroutines.
-			err = e
-		}
+		<-done
 	}
+	return errors.Join(errs...)
+}
 
-	return err // Return the last non-nil error, or nil if no errors.
+// annotate labels err with which physical database produced it: "rw" for
+// index 0, "replica[i-1]" for RO replicas.
+func annotate(i int, err error) error {
+	if i == 0 {
+		return fmt.Errorf("rw: %w", err)
+	}
+	return fmt.Errorf("replica[%d]: %w", i-1, err)
 }
@@ -0,0 +1,89 @@
+package dbrouter
+
+import (
+	"context"
+	"database/sql"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// defaultLagCheckInterval is how often RO replicas are probed for
+// replication lag when WithLagProbe/SetLagProbe does not specify an
+// interval.
+const defaultLagCheckInterval = 5 * time.Second
+
+// LagProbe measures replication lag for a single RO replica. Implementations
+// are driver-specific, for example querying
+// `SELECT EXTRACT(EPOCH FROM now() - pg_last_xact_replay_timestamp())` on
+// Postgres, or `SHOW SLAVE STATUS` on MySQL.
+type LagProbe interface {
+	Lag(ctx context.Context, db *sql.DB) (time.Duration, error)
+}
+
+// unprobedLag is the sentinel stored in lagChecker.lagNS for a replica that
+// has never been successfully probed, so it can be told apart from a replica
+// genuinely measured at zero lag.
+const unprobedLag int64 = -1
+
+// lagChecker periodically probes a fixed set of replicas for replication lag
+// and stores the last successful measurement for each, atomically.
+type lagChecker struct {
+	probe   LagProbe
+	lagNS   []int64 // atomic lag in nanoseconds from the last successful probe, or unprobedLag; one per replica
+	done    chan struct{}
+	stopped sync.Once
+}
+
+// newLagChecker returns a lagChecker for n replicas. Every replica is
+// unprobed (see lagChecker.probed) until its first successful probe.
+func newLagChecker(probe LagProbe, n int) *lagChecker {
+	lagNS := make([]int64, n)
+	for i := range lagNS {
+		lagNS[i] = unprobedLag
+	}
+	return &lagChecker{probe: probe, lagNS: lagNS, done: make(chan struct{})}
+}
+
+// start launches the background probe loop every interval, until stop is
+// called. getReplicas is called fresh on every tick rather than captured
+// once, so a concurrent ReplaceReplica swapping in a new *sql.DB is observed
+// safely instead of racing a stale slice. A failed probe leaves the previous
+// measurement in place rather than clearing it.
+func (lc *lagChecker) start(getReplicas func() []*sql.DB, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	go func() {
+		defer ticker.Stop()
+		for {
+			select {
+			case <-lc.done:
+				return
+			case <-ticker.C:
+				for i, db := range getReplicas() {
+					lag, err := lc.probe.Lag(context.Background(), db)
+					if err != nil {
+						continue
+					}
+					atomic.StoreInt64(&lc.lagNS[i], int64(lag))
+				}
+			}
+		}
+	}()
+}
+
+// stop halts the background probe loop. It is safe to call more than once.
+func (lc *lagChecker) stop() {
+	lc.stopped.Do(func() { close(lc.done) })
+}
+
+// lag returns the last measured replication lag for replica i. It is only
+// meaningful when probed(i) is true.
+func (lc *lagChecker) lag(i int) time.Duration {
+	return time.Duration(atomic.LoadInt64(&lc.lagNS[i]))
+}
+
+// probed reports whether replica i has ever been successfully probed. A
+// replica with no successful probe yet should not be treated as caught up.
+func (lc *lagChecker) probed(i int) bool {
+	return atomic.LoadInt64(&lc.lagNS[i]) != unprobedLag
+}
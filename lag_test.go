@@ -0,0 +1,42 @@
+package dbrouter
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// TestPickReplicaCtxExcludesLaggingAndUnprobedReplicas verifies WithMaxLag's
+// documented contract: a replica whose measured lag exceeds the threshold is
+// skipped, and so is a replica that has never been successfully probed.
+func TestPickReplicaCtxExcludesLaggingAndUnprobedReplicas(t *testing.T) {
+	dbImplementation := newTestDBImplementation(3)
+	dbImplementation.lag = newLagChecker(nil, 3)
+	// Replica 0 is within the threshold; replica 1 exceeds it; replica 2 is
+	// left unprobed.
+	atomic.StoreInt64(&dbImplementation.lag.lagNS[0], int64(10*time.Millisecond))
+	atomic.StoreInt64(&dbImplementation.lag.lagNS[1], int64(time.Second))
+
+	ctx := WithMaxLag(context.Background(), 100*time.Millisecond)
+	for i := 0; i < 20; i++ {
+		idx := dbImplementation.pickReplicaCtx(ctx)
+		if idx != 0 {
+			t.Fatalf("expected only replica 0 to qualify under WithMaxLag, got %d", idx)
+		}
+	}
+}
+
+// TestPickReplicaCtxForcePrimaryBypassesReplicas verifies that
+// WithForcePrimary (and its WithReadFromPrimary alias) route to the RW
+// database regardless of how many healthy replicas are available.
+func TestPickReplicaCtxForcePrimaryBypassesReplicas(t *testing.T) {
+	dbImplementation := newTestDBImplementation(2)
+
+	if idx := dbImplementation.pickReplicaCtx(WithForcePrimary(context.Background())); idx != -1 {
+		t.Fatalf("expected WithForcePrimary to bypass replicas, got index %d", idx)
+	}
+	if idx := dbImplementation.pickReplicaCtx(WithReadFromPrimary(context.Background())); idx != -1 {
+		t.Fatalf("expected WithReadFromPrimary to bypass replicas, got index %d", idx)
+	}
+}
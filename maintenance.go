@@ -0,0 +1,135 @@
+package dbrouter
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sync/atomic"
+	"time"
+)
+
+// drainPollInterval is how often WaitForDrain checks whether a replica's
+// in-flight count has reached zero.
+const drainPollInterval = 10 * time.Millisecond
+
+// replicaState tracks, for a single RO replica, whether it has been taken
+// out of rotation for maintenance and how many queries/statements are
+// currently in flight against it.
+type replicaState struct {
+	maintenance int32 // atomic bool: 1 while the replica is under maintenance
+	inFlight    int64 // atomic count of queries/statements currently using the replica
+}
+
+func (r *replicaState) inMaintenance() bool {
+	return atomic.LoadInt32(&r.maintenance) == 1
+}
+
+// SetReplicaMaintenance puts replica index in or out of maintenance mode.
+// While in maintenance, pickReplica skips it atomically, so no new query or
+// prepared statement is routed to it; in-flight work started before the
+// switch is unaffected. Pair with WaitForDrain to wait for that in-flight
+// work to finish before restarting or replacing the physical database.
+func (dbImplementation *DBImplementation) SetReplicaMaintenance(index int, inMaintenance bool) error {
+	if index < 0 || index >= len(dbImplementation.replicas) {
+		return fmt.Errorf("dbrouter: replica index %d out of range [0,%d)", index, len(dbImplementation.replicas))
+	}
+	value := int32(0)
+	if inMaintenance {
+		value = 1
+	}
+	atomic.StoreInt32(&dbImplementation.replicas[index].maintenance, value)
+	return nil
+}
+
+// WaitForDrain puts replica index into maintenance (if it is not already)
+// and blocks until every in-flight query and prepared statement against it
+// has completed, or ctx is done. It returns so the caller can safely
+// restart or replace the underlying physical database, then call
+// ReplaceReplica. Only executions are counted: a query or statement
+// execution is in flight until its Query/QueryRow(Context) call returns, so
+// rows still being read after that are not waited for, and a prepared
+// statement that is merely open does not count.
+func (dbImplementation *DBImplementation) WaitForDrain(index int, ctx context.Context) error {
+	if err := dbImplementation.SetReplicaMaintenance(index, true); err != nil {
+		return err
+	}
+	replica := dbImplementation.replicas[index]
+	ticker := time.NewTicker(drainPollInterval)
+	defer ticker.Stop()
+	for {
+		if atomic.LoadInt64(&replica.inFlight) == 0 {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// ReplaceReplica swaps in newDB as replica index's physical database, for
+// example after the caller has drained the replica with WaitForDrain and
+// restarted the original database. Every statement currently registered via
+// Prepare/PrepareContext is re-prepared against newDB, and the replica is
+// taken back out of maintenance so it can receive reads again.
+func (dbImplementation *DBImplementation) ReplaceReplica(index int, newDB *sql.DB) error {
+	if index < 0 || index >= len(dbImplementation.replicas) {
+		return fmt.Errorf("dbrouter: replica index %d out of range [0,%d)", index, len(dbImplementation.replicas))
+	}
+
+	dbImplementation.stmtsMu.Lock()
+	stmts := make([]*stmt, 0, len(dbImplementation.liveStmts))
+	for s := range dbImplementation.liveStmts {
+		stmts = append(stmts, s)
+	}
+	dbImplementation.stmtsMu.Unlock()
+
+	for _, s := range stmts {
+		sqlStmt, err := newDB.Prepare(s.query)
+		if err != nil {
+			return fmt.Errorf("dbrouter: re-preparing %q on replacement replica[%d]: %w", s.query, index, err)
+		}
+		s.setROStmt(index, sqlStmt)
+	}
+
+	dbImplementation.roMu.Lock()
+	dbImplementation.readOnlyDBs[index] = newDB
+	dbImplementation.roMu.Unlock()
+
+	atomic.StoreInt64(&dbImplementation.replicas[index].inFlight, 0)
+	return dbImplementation.SetReplicaMaintenance(index, false)
+}
+
+// trackReplica marks the start of a query or statement execution against
+// readOnlyDBs[idx]. idx == -1 (the RW database) is a no-op.
+func (dbImplementation *DBImplementation) trackReplica(idx int) {
+	if idx < 0 {
+		return
+	}
+	atomic.AddInt64(&dbImplementation.replicas[idx].inFlight, 1)
+}
+
+// untrackReplica marks the end of a query or statement execution started by
+// a matching trackReplica call.
+func (dbImplementation *DBImplementation) untrackReplica(idx int) {
+	if idx < 0 {
+		return
+	}
+	atomic.AddInt64(&dbImplementation.replicas[idx].inFlight, -1)
+}
+
+// registerStmt records a non-pinned Stmt so ReplaceReplica can re-prepare it
+// against a replacement replica.
+func (dbImplementation *DBImplementation) registerStmt(s *stmt) {
+	dbImplementation.stmtsMu.Lock()
+	defer dbImplementation.stmtsMu.Unlock()
+	dbImplementation.liveStmts[s] = struct{}{}
+}
+
+// unregisterStmt removes a Stmt from tracking once it has been closed.
+func (dbImplementation *DBImplementation) unregisterStmt(s *stmt) {
+	dbImplementation.stmtsMu.Lock()
+	defer dbImplementation.stmtsMu.Unlock()
+	delete(dbImplementation.liveStmts, s)
+}
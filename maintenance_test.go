@@ -0,0 +1,127 @@
+package dbrouter
+
+import (
+	"context"
+	"database/sql"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// newTestDBImplementation builds a minimal DBImplementation with n RO
+// replicas, all healthy and out of maintenance, for exercising routing and
+// maintenance logic without a real database connection.
+func newTestDBImplementation(n int) *DBImplementation {
+	dbImplementation := &DBImplementation{
+		readWriteDB:      &sql.DB{},
+		readOnlyDBs:      make([]*sql.DB, n),
+		totalConnections: n + 1,
+		loadBalancer:     NewRoundRobinBalancer(),
+	}
+	for i := range dbImplementation.readOnlyDBs {
+		dbImplementation.readOnlyDBs[i] = &sql.DB{}
+	}
+	dbImplementation.health = newHealthChecker(n)
+	dbImplementation.replicas = make([]*replicaState, n)
+	for i := range dbImplementation.replicas {
+		dbImplementation.replicas[i] = &replicaState{}
+	}
+	dbImplementation.liveStmts = make(map[*stmt]struct{})
+	return dbImplementation
+}
+
+// TestWaitForDrainBlocksUntilInFlightReleased guards against a regression
+// where WaitForDrain could observe inFlight == 0 and return while a
+// concurrent caller had already picked the replica but not yet tracked it.
+func TestWaitForDrainBlocksUntilInFlightReleased(t *testing.T) {
+	dbImplementation := newTestDBImplementation(1)
+
+	idx := dbImplementation.pickAndTrackReplica(context.Background())
+	if idx != 0 {
+		t.Fatalf("expected replica 0 to be picked, got %d", idx)
+	}
+
+	drained := make(chan error, 1)
+	go func() {
+		drained <- dbImplementation.WaitForDrain(0, context.Background())
+	}()
+
+	select {
+	case err := <-drained:
+		t.Fatalf("WaitForDrain returned early (err=%v) while a query was still in flight", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	dbImplementation.untrackReplica(idx)
+
+	select {
+	case err := <-drained:
+		if err != nil {
+			t.Fatalf("WaitForDrain returned error: %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("WaitForDrain did not return after the in-flight query finished")
+	}
+}
+
+// TestPickAndTrackReplicaSkipsMaintenance verifies pickAndTrackReplica never
+// selects a replica already under maintenance, and leaves its in-flight
+// counter untouched when it doesn't.
+func TestPickAndTrackReplicaSkipsMaintenance(t *testing.T) {
+	dbImplementation := newTestDBImplementation(1)
+	if err := dbImplementation.SetReplicaMaintenance(0, true); err != nil {
+		t.Fatal(err)
+	}
+
+	idx := dbImplementation.pickAndTrackReplica(context.Background())
+	if idx != -1 {
+		t.Fatalf("expected no replica to qualify while replica 0 is under maintenance, got %d", idx)
+	}
+	if n := atomic.LoadInt64(&dbImplementation.replicas[0].inFlight); n != 0 {
+		t.Fatalf("expected inFlight to remain 0, got %d", n)
+	}
+}
+
+// TestPickAndTrackReplicaConcurrentWithDrain hammers pickAndTrackReplica
+// concurrently with WaitForDrain: once a replica enters maintenance, no
+// concurrent pick should ever select it, which is what closes the window
+// this pair of functions used to race in.
+func TestPickAndTrackReplicaConcurrentWithDrain(t *testing.T) {
+	dbImplementation := newTestDBImplementation(1)
+
+	var wg sync.WaitGroup
+	stop := make(chan struct{})
+	var violations int32
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for {
+			select {
+			case <-stop:
+				return
+			default:
+			}
+			idx := dbImplementation.pickAndTrackReplica(context.Background())
+			if idx == -1 {
+				continue
+			}
+			if dbImplementation.replicas[idx].inMaintenance() {
+				atomic.AddInt32(&violations, 1)
+			}
+			dbImplementation.untrackReplica(idx)
+		}
+	}()
+
+	time.Sleep(5 * time.Millisecond)
+	if err := dbImplementation.WaitForDrain(0, context.Background()); err != nil {
+		t.Fatalf("WaitForDrain: %v", err)
+	}
+	close(stop)
+	wg.Wait()
+
+	if violations > 0 {
+		t.Fatalf("pickAndTrackReplica selected replica 0 %d time(s) after it entered maintenance", violations)
+	}
+}
@@ -0,0 +1,70 @@
+package dbrouter
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+)
+
+// OpenDB mirrors sql.OpenDB(driver.Connector), letting callers plug in a
+// custom driver.Connector (IAM-auth wrappers, Cloud SQL, tracing connectors,
+// …) instead of being restricted to sql.Open(driverName, dsn). The first
+// connector is the RW database; the rest are RO replicas. Open and WrapDBs
+// remain available for the common case.
+func OpenDB(connectors ...driver.Connector) (*DBImplementation, error) {
+	if len(connectors) == 0 {
+		return nil, errors.New("dbrouter: at least one connector is required")
+	}
+	dbImplementation := &DBImplementation{
+		readOnlyDBs:      make([]*sql.DB, len(connectors)-1),
+		totalConnections: len(connectors),
+	}
+	dbImplementation.readWriteDB = sql.OpenDB(connectors[0])
+	for i, connector := range connectors[1:] {
+		dbImplementation.readOnlyDBs[i] = sql.OpenDB(connector)
+	}
+	dbImplementation.applyOptions(nil)
+	return dbImplementation, nil
+}
+
+// DSN pairs a data source name with the driver that should open it, so a
+// single topology can mix drivers.
+type DSN struct {
+	Driver         string
+	DataSourceName string
+}
+
+// OpenMulti is like Open, but each connection carries its own driver name,
+// so a topology can mix drivers — for example a Postgres primary alongside a
+// pgbouncer replica registered under a different driver name. The first DSN
+// is the RW database; the rest are RO replicas.
+func OpenMulti(dsns []DSN, opts ...Option) (*DBImplementation, error) {
+	if len(dsns) == 0 {
+		return nil, errors.New("dbrouter: at least one DSN is required")
+	}
+	dbImplementation := &DBImplementation{
+		readOnlyDBs:      make([]*sql.DB, len(dsns)-1),
+		totalConnections: len(dsns),
+	}
+	// Apply opts once up front so WithMaxConcurrency is already in effect for
+	// the dial loop below; applyOptions re-applies them once the RO replicas
+	// exist, which is harmless since every Option only assigns a field.
+	for _, opt := range opts {
+		opt(dbImplementation)
+	}
+	err := doConcurrentLimit(context.Background(), dbImplementation.totalConnections, dbImplementation.concurrencyLimit, func(_ context.Context, i int) error {
+		var err error
+		if i == 0 {
+			dbImplementation.readWriteDB, err = sql.Open(dsns[i].Driver, dsns[i].DataSourceName)
+			return err
+		}
+		dbImplementation.readOnlyDBs[i-1], err = sql.Open(dsns[i].Driver, dsns[i].DataSourceName)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	dbImplementation.applyOptions(opts)
+	return dbImplementation, nil
+}
@@ -0,0 +1,53 @@
+package dbrouter
+
+import (
+	"context"
+	"database/sql/driver"
+	"testing"
+)
+
+// TestOpenDBRequiresAtLeastOneConnector verifies OpenDB rejects an empty
+// connector list instead of constructing a DBImplementation with no RW
+// database.
+func TestOpenDBRequiresAtLeastOneConnector(t *testing.T) {
+	if _, err := OpenDB(); err == nil {
+		t.Fatal("expected an error when no connectors are provided")
+	}
+}
+
+// TestOpenMultiRequiresAtLeastOneDSN verifies OpenMulti rejects an empty DSN
+// list instead of constructing a DBImplementation with no RW database.
+func TestOpenMultiRequiresAtLeastOneDSN(t *testing.T) {
+	if _, err := OpenMulti(nil); err == nil {
+		t.Fatal("expected an error when no DSNs are provided")
+	}
+}
+
+// TestOpenDBWiresConnectorsToRWAndReplicas verifies OpenDB treats the first
+// connector as the RW database and the rest as RO replicas.
+func TestOpenDBWiresConnectorsToRWAndReplicas(t *testing.T) {
+	dbImplementation, err := OpenDB(fakeConnector{}, fakeConnector{}, fakeConnector{})
+	if err != nil {
+		t.Fatalf("OpenDB: %v", err)
+	}
+	defer dbImplementation.Close()
+
+	if dbImplementation.readWriteDB == nil {
+		t.Fatal("expected a RW database")
+	}
+	if len(dbImplementation.readOnlyDBs) != 2 {
+		t.Fatalf("expected 2 RO replicas, got %d", len(dbImplementation.readOnlyDBs))
+	}
+}
+
+// fakeConnector is a driver.Connector backed by fakeDriver, for tests that
+// need to exercise OpenDB without a real database.
+type fakeConnector struct{}
+
+func (fakeConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	return &fakeConn{}, nil
+}
+
+func (fakeConnector) Driver() driver.Driver {
+	return fakeDriver{}
+}
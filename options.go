@@ -0,0 +1,48 @@
+package dbrouter
+
+import "time"
+
+// Option configures optional behavior on a DBImplementation at construction
+// time, passed to Open or WrapDBsWithOptions. The same settings can be
+// changed later via SetLoadBalancer and SetHealthCheckInterval.
+type Option func(*DBImplementation)
+
+// WithLoadBalancer selects the strategy used to pick a RO replica for reads.
+// The default is round-robin.
+func WithLoadBalancer(lb LoadBalancer) Option {
+	return func(dbImplementation *DBImplementation) {
+		dbImplementation.loadBalancer = lb
+	}
+}
+
+// WithHealthCheckInterval sets how often RO replicas are pinged to detect
+// failures. The default is defaultHealthCheckInterval.
+func WithHealthCheckInterval(interval time.Duration) Option {
+	return func(dbImplementation *DBImplementation) {
+		dbImplementation.healthCheckInterval = interval
+	}
+}
+
+// WithLagProbe enables replication-lag tracking using probe, queried on the
+// given interval. Once configured, WithMaxLag can be used to keep reads off
+// replicas that have fallen too far behind. Without this option, WithMaxLag
+// has no effect.
+func WithLagProbe(probe LagProbe, interval time.Duration) Option {
+	return func(dbImplementation *DBImplementation) {
+		dbImplementation.lagProbe = probe
+		dbImplementation.lagCheckInterval = interval
+	}
+}
+
+// WithMaxConcurrency bounds how many of the underlying physical databases
+// Open, OpenMulti, Close, Ping(Context), Prepare(Context), and Stmt.Close
+// operate on at once, instead of dialing/pinging/preparing/closing all of
+// them in parallel. n <= 0 (the default) means unbounded; a positive n
+// matters for topologies with many replicas, to avoid opening hundreds of
+// driver connections at once. It bounds the concurrent calls, not the
+// number of goroutines started.
+func WithMaxConcurrency(n int) Option {
+	return func(dbImplementation *DBImplementation) {
+		dbImplementation.concurrencyLimit = n
+	}
+}
@@ -7,12 +7,20 @@ import "database/sql"
 // while the remaining connections are treated as read-only (RO) databases.
 // If no connections are provided, it will panic, as an RW connection is mandatory.
 func WrapDBs(dbs ...*sql.DB) DB {
+	return WrapDBsWithOptions(dbs)
+}
+
+// WrapDBsWithOptions is like WrapDBs but additionally accepts Options, for
+// example to configure a non-default LoadBalancer or health-check interval.
+func WrapDBsWithOptions(dbs []*sql.DB, opts ...Option) DB {
 	if len(dbs) == 0 {
 		panic("at least one RW connection is required")
 	}
-	return &DBImplementation{
+	dbImplementation := &DBImplementation{
 		readWriteDB:      dbs[0],   // First DB is the RW (primary) database.
 		readOnlyDBs:      dbs[1:],  // Subsequent DBs are RO (replica) databases.
 		totalConnections: len(dbs), // Total number of connections (RW + RO).
 	}
+	dbImplementation.applyOptions(opts)
+	return dbImplementation
 }
@@ -54,7 +54,7 @@ func TestWrapDBs(t *testing.T) {
 				WrapDBs(tc.input...)
 			} else {
 				// Call WrapDBs with valid input
-				db := WrapDBs(tc.input...)
+				db := WrapDBs(tc.input...).(*DBImplementation)
 
 				// Assert RW DB is correct
 				if db.readWriteDB != tc.expectedRWDB {
@@ -3,6 +3,8 @@ package dbrouter
 import (
 	"context"
 	"database/sql"
+	"regexp"
+	"sync"
 )
 
 // Stmt represents an aggregate prepared statement.
@@ -12,26 +14,76 @@ type Stmt interface {
 	Exec(...interface{}) (sql.Result, error)
 	ExecContext(ctx context.Context, args ...interface{}) (sql.Result, error)
 	Query(...interface{}) (*sql.Rows, error)
+	QueryContext(ctx context.Context, args ...interface{}) (*sql.Rows, error)
 	QueryRow(...interface{}) *sql.Row
+	QueryRowContext(ctx context.Context, args ...interface{}) *sql.Row
+}
+
+// returningPattern matches a RETURNING clause in a query, case-insensitively.
+// Statements that return rows from a write must always execute against the
+// RW database, never a RO replica.
+var returningPattern = regexp.MustCompile(`(?i)\bRETURNING\b`)
+
+// hasReturning reports whether query contains a RETURNING clause.
+func hasReturning(query string) bool {
+	return returningPattern.MatchString(query)
 }
 
 // stmt is the internal implementation of the Stmt interface.
 type stmt struct {
-	db      *DB         // Reference to the DB instance.
-	rwstmt  *sql.Stmt   // Prepared statement for the RW database.
-	rostmts []*sql.Stmt // Prepared statements for the RO databases.
+	db     *DBImplementation // Reference to the DB instance.
+	query  string            // The original query text, empty for a pinned stmt. Used by ReplaceReplica to re-prepare.
+	rwstmt *sql.Stmt         // Prepared statement for the RW database.
+
+	mu      sync.RWMutex // Guards rostmts against concurrent ReplaceReplica swaps.
+	rostmts []*sql.Stmt  // Prepared statements for the RO databases.
+
+	// pinned holds the prepared statement when stmt was built from a single
+	// connection (a Tx or a Conn) rather than from the DBImplementation's
+	// full set of physical databases. When set, both RWStmt and ROStmt
+	// return it: there is only one connection to route to.
+	pinned *sql.Stmt
+
+	// returning marks a statement whose query contains a RETURNING clause,
+	// which forces ROStmt to route to the RW statement instead of a replica.
+	returning bool
 }
 
 // Close closes all prepared statements (RW and RO) concurrently.
 func (s *stmt) Close() error {
-	return doConcurrent(s.db.totalConnections, func(i int) error {
+	if s.pinned != nil {
+		return s.pinned.Close()
+	}
+	s.db.unregisterStmt(s)
+	s.mu.RLock()
+	roStmts := make([]*sql.Stmt, len(s.rostmts))
+	copy(roStmts, s.rostmts)
+	s.mu.RUnlock()
+	return doConcurrentLimit(context.Background(), s.db.totalConnections, s.db.concurrencyLimit, func(_ context.Context, i int) error {
 		if i == 0 {
 			return s.rwstmt.Close() // Close RW statement.
 		}
-		return s.rostmts[i-1].Close() // Close RO statement.
+		return roStmts[i-1].Close() // Close RO statement.
 	})
 }
 
+// getROStmt returns the prepared RO statement at index i, synchronized
+// against a concurrent ReplaceReplica swap.
+func (s *stmt) getROStmt(i int) *sql.Stmt {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.rostmts[i]
+}
+
+// setROStmt replaces the prepared RO statement at index i. Called by
+// ReplaceReplica when re-preparing this statement against a replacement
+// replica.
+func (s *stmt) setROStmt(i int, sqlStmt *sql.Stmt) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.rostmts[i] = sqlStmt
+}
+
 // Exec executes a prepared statement on the RW database with the provided arguments.
 // It returns a Result summarizing the effect of the statement.
 func (s *stmt) Exec(args ...interface{}) (sql.Result, error) {
@@ -45,35 +97,106 @@ func (s *stmt) ExecContext(ctx context.Context, args ...interface{}) (sql.Result
 
 // Query executes a prepared query on a RO database and returns the results as *sql.Rows.
 func (s *stmt) Query(args ...interface{}) (*sql.Rows, error) {
-	return s.ROStmt().Query(args...)
+	roStmt, idx := s.trackedROStmtAndIndex()
+	defer s.db.untrackReplica(idx)
+	return roStmt.Query(args...)
 }
 
-// QueryContext executes a prepared query with context on a RO database and returns the results as *sql.Rows.
+// QueryContext executes a prepared query with context on a RO database and
+// returns the results as *sql.Rows. If ctx carries WithMaxLag or
+// WithForcePrimary/WithReadFromPrimary, routing honors them, falling back to
+// the RW statement when no replica qualifies.
 func (s *stmt) QueryContext(ctx context.Context, args ...interface{}) (*sql.Rows, error) {
-	return s.ROStmt().QueryContext(ctx, args...)
+	roStmt, idx := s.trackedROStmtAndIndexCtx(ctx)
+	defer s.db.untrackReplica(idx)
+	return roStmt.QueryContext(ctx, args...)
 }
 
 // QueryRow executes a query on a RO database and returns at most one row.
 // Errors are deferred until Scan is called on the returned *sql.Row.
 func (s *stmt) QueryRow(args ...interface{}) *sql.Row {
-	return s.ROStmt().QueryRow(args...)
+	roStmt, idx := s.trackedROStmtAndIndex()
+	defer s.db.untrackReplica(idx)
+	return roStmt.QueryRow(args...)
 }
 
-// QueryRowContext executes a query with context on a RO database, returning a single row.
-// Errors are deferred until Scan is called on the returned *sql.Row.
+// QueryRowContext executes a query with context on a RO database, returning
+// a single row. If ctx carries WithMaxLag or
+// WithForcePrimary/WithReadFromPrimary, routing honors them, falling back to
+// the RW statement when no replica qualifies. Errors are deferred until Scan
+// is called on the returned *sql.Row.
 func (s *stmt) QueryRowContext(ctx context.Context, args ...interface{}) *sql.Row {
-	return s.ROStmt().QueryRowContext(ctx, args...)
+	roStmt, idx := s.trackedROStmtAndIndexCtx(ctx)
+	defer s.db.untrackReplica(idx)
+	return roStmt.QueryRowContext(ctx, args...)
 }
 
-// ROStmt returns the prepared statement for a RO database, selected using round-robin.
+// ROStmt returns the prepared statement to use for reads, as chosen by
+// roStmtAndIndex.
 func (s *stmt) ROStmt() *sql.Stmt {
-	if len(s.rostmts) == 0 {
-		return s.rwstmt // If no RO statements exist, fall back to the RW statement.
+	roStmt, _ := s.roStmtAndIndex()
+	return roStmt
+}
+
+// roStmtAndIndex is roStmtAndIndexCtx with a background context, for call
+// sites with no context of their own (Query, QueryRow).
+func (s *stmt) roStmtAndIndex() (*sql.Stmt, int) {
+	return s.roStmtAndIndexCtx(context.Background())
+}
+
+// roStmtAndIndexCtx returns the prepared statement to use for reads, along
+// with the readOnlyDBs index it was prepared against (-1 if it is the RW
+// statement). A pinned statement (prepared on a Tx or a Conn) is always
+// returned as-is. Otherwise, a statement whose query contains RETURNING is
+// routed to the RW statement so the returned rows reflect the write;
+// everything else is routed to a statement chosen by pickReplicaCtx (which
+// honors WithMaxLag and WithForcePrimary/WithReadFromPrimary on ctx),
+// falling back to the RW statement when none qualify.
+func (s *stmt) roStmtAndIndexCtx(ctx context.Context) (*sql.Stmt, int) {
+	if s.pinned != nil {
+		return s.pinned, -1
+	}
+	if s.returning {
+		return s.rwstmt, -1
 	}
-	return s.rostmts[s.db.roundRobin(len(s.rostmts))]
+	idx := s.db.pickReplicaCtx(ctx)
+	if idx == -1 {
+		return s.rwstmt, -1 // No qualifying RO statements; fall back to the RW statement.
+	}
+	return s.getROStmt(idx), idx
+}
+
+// trackedROStmtAndIndex is trackedROStmtAndIndexCtx with a background
+// context, for call sites with no context of their own (Query, QueryRow).
+func (s *stmt) trackedROStmtAndIndex() (*sql.Stmt, int) {
+	return s.trackedROStmtAndIndexCtx(context.Background())
+}
+
+// trackedROStmtAndIndexCtx is roStmtAndIndexCtx, but additionally tracks the
+// chosen replica via pickAndTrackReplica as part of the same step, instead
+// of leaving the caller to track it separately after the pick — see
+// pickAndTrackReplica for why that separation is unsafe. Used by
+// Query/QueryContext/QueryRow/QueryRowContext, which execute immediately and
+// untrack when done; ROStmt does not use this, since it hands the statement
+// to the caller for indefinite use.
+func (s *stmt) trackedROStmtAndIndexCtx(ctx context.Context) (*sql.Stmt, int) {
+	if s.pinned != nil {
+		return s.pinned, -1
+	}
+	if s.returning {
+		return s.rwstmt, -1
+	}
+	idx := s.db.pickAndTrackReplica(ctx)
+	if idx == -1 {
+		return s.rwstmt, -1
+	}
+	return s.getROStmt(idx), idx
 }
 
 // RWStmt returns the prepared statement for the RW (master) database.
 func (s *stmt) RWStmt() *sql.Stmt {
+	if s.pinned != nil {
+		return s.pinned
+	}
 	return s.rwstmt
 }
@@ -0,0 +1,114 @@
+package dbrouter
+
+import (
+	"context"
+	"database/sql"
+)
+
+// Tx represents a transaction running on the RW database's connection.
+// Because a transaction is pinned to a single physical connection, every
+// statement prepared through Tx is routed to that same connection, so reads
+// made inside the transaction observe the writes made earlier in it rather
+// than being load-balanced out to a RO replica.
+type Tx interface {
+	Commit() error
+	Rollback() error
+	Exec(query string, args ...interface{}) (sql.Result, error)
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	Prepare(query string) (Stmt, error)
+	PrepareContext(ctx context.Context, query string) (Stmt, error)
+	Query(query string, args ...interface{}) (*sql.Rows, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRow(query string, args ...interface{}) *sql.Row
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+	Stmt(s Stmt) Stmt
+	StmtContext(ctx context.Context, s Stmt) Stmt
+}
+
+// tx is the internal implementation of the Tx interface. It wraps a *sql.Tx
+// obtained from the RW database and hands out Stmts pinned to it.
+type tx struct {
+	db    *DBImplementation
+	sqlTx *sql.Tx
+}
+
+// Commit commits the transaction.
+func (t *tx) Commit() error {
+	return t.sqlTx.Commit()
+}
+
+// Rollback aborts the transaction.
+func (t *tx) Rollback() error {
+	return t.sqlTx.Rollback()
+}
+
+// Exec executes a query within the transaction without returning rows.
+func (t *tx) Exec(query string, args ...interface{}) (sql.Result, error) {
+	return t.sqlTx.Exec(query, args...)
+}
+
+// ExecContext executes a query with context within the transaction without returning rows.
+func (t *tx) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	return t.sqlTx.ExecContext(ctx, query, args...)
+}
+
+// Prepare prepares query on the transaction's own connection. The returned
+// Stmt is pinned: it never fans out to RO databases, since reads within a
+// transaction must see the transaction's own writes.
+func (t *tx) Prepare(query string) (Stmt, error) {
+	sqlStmt, err := t.sqlTx.Prepare(query)
+	if err != nil {
+		return nil, err
+	}
+	return &stmt{db: t.db, pinned: sqlStmt}, nil
+}
+
+// PrepareContext is like Prepare but accepts a context.
+func (t *tx) PrepareContext(ctx context.Context, query string) (Stmt, error) {
+	sqlStmt, err := t.sqlTx.PrepareContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	return &stmt{db: t.db, pinned: sqlStmt}, nil
+}
+
+// Query executes a query within the transaction and returns rows.
+func (t *tx) Query(query string, args ...interface{}) (*sql.Rows, error) {
+	return t.sqlTx.Query(query, args...)
+}
+
+// QueryContext is like Query but accepts a context.
+func (t *tx) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	return t.sqlTx.QueryContext(ctx, query, args...)
+}
+
+// QueryRow executes a query within the transaction and returns at most one row.
+func (t *tx) QueryRow(query string, args ...interface{}) *sql.Row {
+	return t.sqlTx.QueryRow(query, args...)
+}
+
+// QueryRowContext is like QueryRow but accepts a context.
+func (t *tx) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	return t.sqlTx.QueryRowContext(ctx, query, args...)
+}
+
+// Stmt returns a transaction-specific prepared statement from an existing
+// Stmt prepared on the RW database, mirroring (*sql.Tx).Stmt. The returned
+// Stmt is pinned to the transaction's connection; a Stmt not created by this
+// package is returned unchanged.
+func (t *tx) Stmt(s Stmt) Stmt {
+	rw, ok := s.(*stmt)
+	if !ok {
+		return s
+	}
+	return &stmt{db: t.db, pinned: t.sqlTx.Stmt(rw.RWStmt())}
+}
+
+// StmtContext is like Stmt but accepts a context.
+func (t *tx) StmtContext(ctx context.Context, s Stmt) Stmt {
+	rw, ok := s.(*stmt)
+	if !ok {
+		return s
+	}
+	return &stmt{db: t.db, pinned: t.sqlTx.StmtContext(ctx, rw.RWStmt())}
+}
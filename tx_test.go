@@ -0,0 +1,36 @@
+package dbrouter
+
+import (
+	"database/sql"
+	"testing"
+)
+
+// TestTxPrepareReturnsPinnedStmt verifies that a Stmt prepared within a Tx is
+// pinned to the transaction's own connection rather than being routed to a
+// RO replica, so reads inside the transaction observe its own writes.
+func TestTxPrepareReturnsPinnedStmt(t *testing.T) {
+	dbImplementation := &DBImplementation{readWriteDB: newFakeDB(), readOnlyDBs: []*sql.DB{newFakeDB()}}
+
+	txn, err := dbImplementation.Begin()
+	if err != nil {
+		t.Fatalf("Begin: %v", err)
+	}
+	defer txn.Rollback()
+
+	s, err := txn.Prepare("SELECT 1")
+	if err != nil {
+		t.Fatalf("Prepare: %v", err)
+	}
+	defer s.Close()
+
+	internal, ok := s.(*stmt)
+	if !ok {
+		t.Fatalf("expected *stmt, got %T", s)
+	}
+	if internal.pinned == nil {
+		t.Fatal("expected Tx.Prepare to return a pinned stmt")
+	}
+	if _, idx := internal.roStmtAndIndex(); idx != -1 {
+		t.Fatalf("expected a pinned stmt to report index -1, got %d", idx)
+	}
+}